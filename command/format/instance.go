@@ -0,0 +1,255 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/terraform/config/configschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// RenderInstance produces an HCL-like rendering of the given resource
+// instance object, as decoded using the given schema, for display to an
+// end-user.
+//
+// Attributes are rendered in name order with their equals signs aligned,
+// followed by any nested blocks, also in name order. The entries of map and
+// object values are aligned in the same way. Null attributes and blocks are
+// omitted, unknown values and block elements are rendered as "<computed>",
+// and the values of attributes that the schema marks as sensitive are
+// rendered as "<sensitive>".
+//
+// Strings, block labels, and map keys that are not valid identifiers are
+// quoted and escaped as in the HCL native syntax, including escaping of the
+// "${" and "%{" template sequences so that literal values are not mistaken
+// for interpolations. If the given value is not a known, non-null object
+// then the result is empty.
+func RenderInstance(v cty.Value, schema *configschema.Block) string {
+	var buf bytes.Buffer
+	renderBlockBody(&buf, v, schema, 0)
+	return buf.String()
+}
+
+func renderBlockBody(buf *bytes.Buffer, v cty.Value, schema *configschema.Block, indent int) {
+	if schema == nil || v.IsNull() || !v.IsKnown() || !v.Type().IsObjectType() {
+		return
+	}
+
+	attrNames := make([]string, 0, len(schema.Attributes))
+	nameLen := 0
+	for name := range schema.Attributes {
+		if !v.Type().HasAttribute(name) || v.GetAttr(name).IsNull() {
+			continue
+		}
+		attrNames = append(attrNames, name)
+		if len(name) > nameLen {
+			nameLen = len(name)
+		}
+	}
+	sort.Strings(attrNames)
+
+	for _, name := range attrNames {
+		av := v.GetAttr(name)
+		buf.WriteString(strings.Repeat(" ", indent))
+		fmt.Fprintf(buf, "%-*s = ", nameLen, name)
+		if schema.Attributes[name].Sensitive {
+			buf.WriteString("<sensitive>")
+		} else {
+			renderValue(buf, av, indent)
+		}
+		buf.WriteString("\n")
+	}
+
+	blockNames := make([]string, 0, len(schema.BlockTypes))
+	for name := range schema.BlockTypes {
+		blockNames = append(blockNames, name)
+	}
+	sort.Strings(blockNames)
+
+	for _, name := range blockNames {
+		blockS := schema.BlockTypes[name]
+		if !v.Type().HasAttribute(name) {
+			continue
+		}
+		bv := v.GetAttr(name)
+		if bv.IsNull() {
+			continue
+		}
+		if !bv.IsKnown() {
+			buf.WriteString(strings.Repeat(" ", indent))
+			fmt.Fprintf(buf, "%s = <computed>\n", name)
+			continue
+		}
+
+		switch blockS.Nesting {
+		case configschema.NestingSingle:
+			renderBlock(buf, name, "", bv, &blockS.Block, indent)
+		case configschema.NestingList, configschema.NestingSet:
+			for it := bv.ElementIterator(); it.Next(); {
+				_, ev := it.Element()
+				if !ev.IsKnown() {
+					buf.WriteString(strings.Repeat(" ", indent))
+					fmt.Fprintf(buf, "%s = <computed>\n", name)
+					continue
+				}
+				renderBlock(buf, name, "", ev, &blockS.Block, indent)
+			}
+		case configschema.NestingMap:
+			// Map element iteration is in key order
+			for it := bv.ElementIterator(); it.Next(); {
+				ek, ev := it.Element()
+				if !ev.IsKnown() {
+					buf.WriteString(strings.Repeat(" ", indent))
+					fmt.Fprintf(buf, "%s %s = <computed>\n", name, quoteHCLString(ek.AsString()))
+					continue
+				}
+				renderBlock(buf, name, ek.AsString(), ev, &blockS.Block, indent)
+			}
+		}
+	}
+}
+
+func renderBlock(buf *bytes.Buffer, name, label string, v cty.Value, schema *configschema.Block, indent int) {
+	buf.WriteString(strings.Repeat(" ", indent))
+	buf.WriteString(name)
+	if label != "" {
+		fmt.Fprintf(buf, " %s", quoteHCLString(label))
+	}
+	buf.WriteString(" {\n")
+	renderBlockBody(buf, v, schema, indent+2)
+	buf.WriteString(strings.Repeat(" ", indent))
+	buf.WriteString("}\n")
+}
+
+func renderValue(buf *bytes.Buffer, v cty.Value, indent int) {
+	if !v.IsKnown() {
+		buf.WriteString("<computed>")
+		return
+	}
+	if v.IsNull() {
+		buf.WriteString("null")
+		return
+	}
+
+	ty := v.Type()
+	switch {
+	case ty == cty.String:
+		buf.WriteString(quoteHCLString(v.AsString()))
+	case ty == cty.Number:
+		buf.WriteString(v.AsBigFloat().Text('f', -1))
+	case ty == cty.Bool:
+		if v.True() {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		if v.LengthInt() == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for it := v.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			buf.WriteString(strings.Repeat(" ", indent+2))
+			renderValue(buf, ev, indent+2)
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteString("]")
+	case ty.IsMapType() || ty.IsObjectType():
+		var empty bool
+		if ty.IsObjectType() {
+			empty = len(ty.AttributeTypes()) == 0
+		} else {
+			empty = v.LengthInt() == 0
+		}
+		if empty {
+			buf.WriteString("{}")
+			return
+		}
+		var keys []string
+		var vals []cty.Value
+		keyLen := 0
+		for it := v.ElementIterator(); it.Next(); {
+			ek, ev := it.Element()
+			key := ek.AsString()
+			if !hclsyntax.ValidIdentifier(key) {
+				key = quoteHCLString(key)
+			}
+			keys = append(keys, key)
+			vals = append(vals, ev)
+			if l := utf8.RuneCountInString(key); l > keyLen {
+				keyLen = l
+			}
+		}
+
+		buf.WriteString("{\n")
+		for i, key := range keys {
+			buf.WriteString(strings.Repeat(" ", indent+2))
+			fmt.Fprintf(buf, "%-*s = ", keyLen, key)
+			renderValue(buf, vals[i], indent+2)
+			buf.WriteString("\n")
+		}
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteString("}")
+	default:
+		buf.WriteString(v.GoString())
+	}
+}
+
+// quoteHCLString returns the given string as a quoted string literal in the
+// HCL native syntax.
+func quoteHCLString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch r {
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '$', '%':
+			// "${" and "%{" begin template sequences, and are escaped by
+			// doubling the introducer. A longer run of introducers before
+			// the brace would be misread as a different escape, so all but
+			// the last introducer in such a run are written as unicode
+			// escapes instead. Runs not followed by a brace are literal.
+			end := i
+			for end < len(s) && rune(s[end]) == r {
+				end++
+			}
+			if end < len(s) && s[end] == '{' {
+				for j := i; j < end-1; j++ {
+					fmt.Fprintf(&buf, "\\u%04x", r)
+				}
+				buf.WriteRune(r)
+				buf.WriteRune(r)
+			} else {
+				buf.WriteString(s[i:end])
+			}
+			i = end
+			continue
+		default:
+			if r < ' ' || r == 0x7f {
+				fmt.Fprintf(&buf, "\\u%04x", r)
+			} else {
+				buf.WriteString(s[i : i+size])
+			}
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
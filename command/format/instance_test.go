@@ -0,0 +1,217 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/terraform/config/configschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestRenderInstance(t *testing.T) {
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id": {
+				Type:     cty.String,
+				Computed: true,
+			},
+			"ami": {
+				Type:     cty.String,
+				Required: true,
+			},
+			"password": {
+				Type:      cty.String,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"count": {
+				Type:     cty.Number,
+				Optional: true,
+			},
+			"tags": {
+				Type:     cty.Map(cty.String),
+				Optional: true,
+			},
+			"zones": {
+				Type:     cty.List(cty.String),
+				Optional: true,
+			},
+			"description": {
+				Type:     cty.String,
+				Optional: true,
+			},
+		},
+		BlockTypes: map[string]*configschema.NestedBlock{
+			"disk": {
+				Nesting: configschema.NestingList,
+				Block: configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"size": {
+							Type:     cty.Number,
+							Required: true,
+						},
+						"encrypted": {
+							Type:     cty.Bool,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"network": {
+				Nesting: configschema.NestingMap,
+				Block: configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"address": {
+							Type:     cty.String,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	v := cty.ObjectVal(map[string]cty.Value{
+		"id":          cty.StringVal("i-abc123"),
+		"ami":         cty.StringVal("ami-123"),
+		"password":    cty.StringVal("hunter2"),
+		"count":       cty.NumberFloatVal(1.5),
+		"description": cty.StringVal("${foo} and %{bar}\n\"quoted\" 100%"),
+		"tags": cty.MapVal(map[string]cty.Value{
+			"Name":                    cty.StringVal("foo"),
+			"kubernetes.io/cluster/x": cty.StringVal("owned"),
+			"my key":                  cty.StringVal("v"),
+		}),
+		"zones": cty.ListValEmpty(cty.String),
+		"disk": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"size":      cty.NumberIntVal(10),
+				"encrypted": cty.True,
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"size":      cty.NumberIntVal(20),
+				"encrypted": cty.NullVal(cty.Bool),
+			}),
+			cty.UnknownVal(schema.BlockTypes["disk"].ImpliedType()),
+		}),
+		"network": cty.MapVal(map[string]cty.Value{
+			"private": cty.ObjectVal(map[string]cty.Value{
+				"address": cty.UnknownVal(cty.String),
+			}),
+			"public": cty.UnknownVal(schema.BlockTypes["network"].ImpliedType()),
+		}),
+	})
+
+	got := RenderInstance(v, schema)
+	want := `ami         = "ami-123"
+count       = 1.5
+description = "$${foo} and %%{bar}\n\"quoted\" 100%"
+id          = "i-abc123"
+password    = <sensitive>
+tags        = {
+  Name                      = "foo"
+  "kubernetes.io/cluster/x" = "owned"
+  "my key"                  = "v"
+}
+zones       = []
+disk {
+  encrypted = true
+  size      = 10
+}
+disk {
+  size = 20
+}
+disk = <computed>
+network "private" {
+  address = <computed>
+}
+network "public" = <computed>
+`
+	if got != want {
+		t.Fatalf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestQuoteHCLString(t *testing.T) {
+	// Each quoted string must parse as an HCL expression that evaluates
+	// back to exactly the original string.
+	tests := []string{
+		"",
+		"foo",
+		"${foo}",
+		"%{if true}yes%{endif}",
+		"$$ and %% and $ and %",
+		"$${foo} and %%{bar}",
+		"$$$${foo} and %%%{bar}",
+		"{$}{%}",
+		"trailing $",
+		"line one\nline two\r\n\ttabbed",
+		// The vendored HCL scanner rejects a string ending in an escaped
+		// backslash, so the backslashes here are kept away from the end.
+		`"quoted" and \back\slash`,
+		"control \x01 char",
+		"unicode héllo ✓",
+	}
+
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			quoted := quoteHCLString(test)
+			expr, diags := hclsyntax.ParseExpression([]byte(quoted), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("failed to parse %s: %s", quoted, diags.Error())
+			}
+			got, diags := expr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatalf("failed to evaluate %s: %s", quoted, diags.Error())
+			}
+			if !got.RawEquals(cty.StringVal(test)) {
+				t.Fatalf("wrong result\nquoted: %s\ngot:    %#v\nwant:   %#v", quoted, got, test)
+			}
+		})
+	}
+}
+
+func TestRenderInstance_null(t *testing.T) {
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id": {
+				Type:     cty.String,
+				Computed: true,
+			},
+		},
+	}
+
+	got := RenderInstance(cty.NullVal(schema.ImpliedType()), schema)
+	if got != "" {
+		t.Fatalf("wrong result\ngot:  %q\nwant: %q", got, "")
+	}
+}
+
+func TestRenderInstance_nonObject(t *testing.T) {
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id": {
+				Type:     cty.String,
+				Computed: true,
+			},
+		},
+	}
+
+	tests := map[string]cty.Value{
+		"empty map": cty.MapValEmpty(cty.String),
+		"string":    cty.StringVal("i-abc123"),
+		"list": cty.ListVal([]cty.Value{
+			cty.StringVal("i-abc123"),
+		}),
+	}
+
+	for name, v := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := RenderInstance(v, schema)
+			if got != "" {
+				t.Fatalf("wrong result\ngot:  %q\nwant: %q", got, "")
+			}
+		})
+	}
+}
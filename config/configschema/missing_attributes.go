@@ -0,0 +1,74 @@
+package configschema
+
+import (
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// MissingAttributes returns the paths of all attributes that the given schema
+// declares but which are null or entirely absent in the given value.
+//
+// This is intended for migration tooling: after a provider schema adds new
+// attributes, values decoded from older state will have no value for them,
+// and the returned paths identify where defaults might need to be filled in.
+// A value decoded from state cannot distinguish an absent attribute from one
+// that was explicitly null, so both are reported.
+//
+// Nested blocks are visited recursively for each element present in the
+// value. Null or unknown values, and unknown elements of nested blocks, are
+// skipped since their content cannot be inspected. The result is ordered by
+// attribute name, with attributes preceding nested blocks at each level.
+func MissingAttributes(v cty.Value, schema *Block) []cty.Path {
+	return missingAttributes(nil, v, schema)
+}
+
+func missingAttributes(path cty.Path, v cty.Value, schema *Block) []cty.Path {
+	if schema == nil || v.IsNull() || !v.IsKnown() || !v.Type().IsObjectType() {
+		return nil
+	}
+
+	var ret []cty.Path
+	ty := v.Type()
+
+	attrNames := make([]string, 0, len(schema.Attributes))
+	for name := range schema.Attributes {
+		attrNames = append(attrNames, name)
+	}
+	sort.Strings(attrNames)
+	for _, name := range attrNames {
+		if !ty.HasAttribute(name) || v.GetAttr(name).IsNull() {
+			ret = append(ret, path.GetAttr(name))
+		}
+	}
+
+	blockNames := make([]string, 0, len(schema.BlockTypes))
+	for name := range schema.BlockTypes {
+		blockNames = append(blockNames, name)
+	}
+	sort.Strings(blockNames)
+	for _, name := range blockNames {
+		blockS := schema.BlockTypes[name]
+		if !ty.HasAttribute(name) {
+			continue
+		}
+		bv := v.GetAttr(name)
+		blockPath := path.GetAttr(name)
+
+		switch blockS.Nesting {
+		case NestingSingle:
+			ret = append(ret, missingAttributes(blockPath, bv, &blockS.Block)...)
+		case NestingList, NestingSet, NestingMap:
+			if bv.IsNull() || !bv.IsKnown() {
+				continue
+			}
+			for it := bv.ElementIterator(); it.Next(); {
+				// For sets, the iterator returns each element as its own key.
+				ek, ev := it.Element()
+				ret = append(ret, missingAttributes(blockPath.Index(ek), ev, &blockS.Block)...)
+			}
+		}
+	}
+
+	return ret
+}
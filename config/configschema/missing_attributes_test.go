@@ -0,0 +1,110 @@
+package configschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestMissingAttributes(t *testing.T) {
+	schema := &Block{
+		Attributes: map[string]*Attribute{
+			"id": {
+				Type:     cty.String,
+				Computed: true,
+			},
+			"name": {
+				Type:     cty.String,
+				Required: true,
+			},
+			"new_attr": {
+				Type:     cty.String,
+				Optional: true,
+			},
+		},
+		BlockTypes: map[string]*NestedBlock{
+			"disk": {
+				Nesting: NestingList,
+				Block: Block{
+					Attributes: map[string]*Attribute{
+						"size": {
+							Type:     cty.Number,
+							Required: true,
+						},
+						"encrypted": {
+							Type:     cty.Bool,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		Value cty.Value
+		Want  []cty.Path
+	}{
+		"null": {
+			cty.NullVal(schema.ImpliedType()),
+			nil,
+		},
+		"complete": {
+			cty.ObjectVal(map[string]cty.Value{
+				"id":       cty.StringVal("i-abc123"),
+				"name":     cty.StringVal("foo"),
+				"new_attr": cty.StringVal("bar"),
+				"disk": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"size":      cty.NumberIntVal(10),
+						"encrypted": cty.True,
+					}),
+				}),
+			}),
+			nil,
+		},
+		"added to schema": {
+			// A value decoded using the schema before new_attr and
+			// disk.encrypted were added.
+			cty.ObjectVal(map[string]cty.Value{
+				"id":   cty.StringVal("i-abc123"),
+				"name": cty.StringVal("foo"),
+				"disk": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"size": cty.NumberIntVal(10),
+					}),
+				}),
+			}),
+			[]cty.Path{
+				{cty.GetAttrStep{Name: "new_attr"}},
+				{
+					cty.GetAttrStep{Name: "disk"},
+					cty.IndexStep{Key: cty.NumberIntVal(0)},
+					cty.GetAttrStep{Name: "encrypted"},
+				},
+			},
+		},
+		"null attributes": {
+			cty.ObjectVal(map[string]cty.Value{
+				"id":       cty.NullVal(cty.String),
+				"name":     cty.StringVal("foo"),
+				"new_attr": cty.NullVal(cty.String),
+				"disk":     cty.ListValEmpty(schema.BlockTypes["disk"].ImpliedType()),
+			}),
+			[]cty.Path{
+				{cty.GetAttrStep{Name: "id"}},
+				{cty.GetAttrStep{Name: "new_attr"}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := MissingAttributes(test.Value, schema)
+			if !reflect.DeepEqual(got, test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
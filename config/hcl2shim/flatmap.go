@@ -0,0 +1,298 @@
+package hcl2shim
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/config/configschema"
+	"github.com/hashicorp/terraform/tfdiags"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValidateFlatmapAgainstSchema checks that the given flatmap has a shape
+// that is plausible for an object conforming to the given schema, without
+// actually decoding any values.
+//
+// This is intended as a cheap pre-check before a full decode, and so it
+// checks only the following:
+//
+//   - every top-level key belongs to an attribute or block type in the schema
+//   - every required attribute has at least one key in the map
+//   - every list, set or map block type that has keys in the map also has
+//     the corresponding count marker ("#" for lists and sets, "%" for maps)
+//   - no block, or element of a block collection, is given as a single
+//     scalar key
+//
+// Nested blocks are checked recursively for each element found in the map.
+// The keys are sorted once and then partitioned between blocks as the schema
+// is walked, so the cost is proportional to the number of keys times the
+// nesting depth rather than growing with the square of the map size.
+//
+// A nil schema is treated as having nothing to check, and so produces no
+// diagnostics.
+func ValidateFlatmapAgainstSchema(m map[string]string, schema *configschema.Block) tfdiags.Diagnostics {
+	if schema == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return validateFlatmapBlock(m, keys, "", schema)
+}
+
+// validateFlatmapBlock validates the given keys, all of which must start with
+// the given prefix, against the given block schema.
+func validateFlatmapBlock(m map[string]string, keys []string, prefix string, schema *configschema.Block) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	names, groups := groupFlatmapKeys(keys, prefix)
+	for _, name := range names {
+		if _, exists := schema.Attributes[name]; exists {
+			continue
+		}
+		if _, exists := schema.BlockTypes[name]; exists {
+			continue
+		}
+		diags = diags.Append(fmt.Errorf(
+			"%s%s: unexpected key for this schema", prefix, name,
+		))
+	}
+
+	attrNames := make([]string, 0, len(schema.Attributes))
+	for name := range schema.Attributes {
+		attrNames = append(attrNames, name)
+	}
+	sort.Strings(attrNames)
+	for _, name := range attrNames {
+		attrS := schema.Attributes[name]
+		if !attrS.Required {
+			continue
+		}
+		if len(groups[name]) == 0 {
+			diags = diags.Append(fmt.Errorf(
+				"%s%s: required attribute is missing", prefix, name,
+			))
+		}
+	}
+
+	blockNames := make([]string, 0, len(schema.BlockTypes))
+	for name := range schema.BlockTypes {
+		blockNames = append(blockNames, name)
+	}
+	sort.Strings(blockNames)
+	for _, name := range blockNames {
+		blockS := schema.BlockTypes[name]
+		blockKeys := groups[name]
+		if len(blockKeys) == 0 {
+			continue
+		}
+
+		blockPrefix := prefix + name + "."
+		blockKeys, scalar := flatmapNestedKeys(blockKeys, prefix+name)
+		if scalar {
+			diags = diags.Append(fmt.Errorf(
+				"%s%s: unexpected key for this schema", prefix, name,
+			))
+			if len(blockKeys) == 0 {
+				continue
+			}
+		}
+
+		switch blockS.Nesting {
+		case configschema.NestingSingle:
+			diags = diags.Append(validateFlatmapBlock(m, blockKeys, blockPrefix, &blockS.Block))
+		case configschema.NestingList, configschema.NestingSet, configschema.NestingMap:
+			marker := "#"
+			if blockS.Nesting == configschema.NestingMap {
+				marker = "%"
+			}
+
+			elemNames, elemGroups := groupFlatmapKeys(blockKeys, blockPrefix)
+			if len(elemNames) == 0 {
+				continue
+			}
+			if _, exists := m[blockPrefix+marker]; !exists {
+				diags = diags.Append(fmt.Errorf(
+					"%s%s: count marker %q is missing", prefix, name, blockPrefix+marker,
+				))
+			}
+			for _, elemName := range elemNames {
+				if elemName == marker {
+					continue
+				}
+
+				elemKeys, scalar := flatmapNestedKeys(elemGroups[elemName], blockPrefix+elemName)
+				if scalar {
+					diags = diags.Append(fmt.Errorf(
+						"%s%s: unexpected key for this schema", blockPrefix, elemName,
+					))
+					if len(elemKeys) == 0 {
+						continue
+					}
+				}
+				diags = diags.Append(validateFlatmapBlock(m, elemKeys, blockPrefix+elemName+".", &blockS.Block))
+			}
+		}
+	}
+
+	return diags
+}
+
+// groupFlatmapKeys groups the given keys, all of which must start with the
+// given prefix, by their first path segment after that prefix. The returned
+// names are sorted, and each group retains the order of the given keys.
+func groupFlatmapKeys(keys []string, prefix string) ([]string, map[string][]string) {
+	var names []string
+	groups := make(map[string][]string)
+	for _, k := range keys {
+		name := k[len(prefix):]
+		if idx := strings.Index(name, "."); idx != -1 {
+			name = name[:idx]
+		}
+		if _, exists := groups[name]; !exists {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], k)
+	}
+	sort.Strings(names)
+	return names, groups
+}
+
+// flatmapNestedKeys returns the subset of the given keys that are nested
+// beneath the given key, all of which must either be that key or be nested
+// beneath it. The second return value is true if the key itself is present,
+// meaning that it has a scalar value.
+func flatmapNestedKeys(keys []string, key string) ([]string, bool) {
+	nested := make([]string, 0, len(keys))
+	scalar := false
+	for _, k := range keys {
+		if k == key {
+			scalar = true
+			continue
+		}
+		nested = append(nested, k)
+	}
+	return nested, scalar
+}
+
+// InferTypeFromFlatmap makes a best-effort guess at the type of the value
+// stored under the given key in the given flatmap, using only the structure
+// of the keys. An empty key infers the type of the whole map, which is
+// always an object type.
+//
+// The heuristic is as follows:
+//
+//   - a key that is present exactly is a string
+//   - a "#" count marker, or child keys that are all numeric, indicate a list
+//   - a "%" count marker indicates a map
+//   - any other child keys indicate an object with one attribute per child
+//
+// Since flatmap does not record primitive types, all leaf values are inferred
+// as strings. Collection element types are inferred from the elements
+// present, falling back to cty.DynamicPseudoType if the collection is empty
+// or its elements disagree. If there is nothing at all under the given key,
+// the result is cty.DynamicPseudoType. Keys with an empty path segment, such
+// as "" or ".foo", are malformed and are ignored.
+func InferTypeFromFlatmap(m map[string]string, prefix string) cty.Type {
+	if prefix == "" {
+		return inferFlatmapObjectType(m, "")
+	}
+
+	if _, exists := m[prefix]; exists {
+		return cty.String
+	}
+
+	childPrefix := prefix + "."
+	names := flatmapChildNames(m, childPrefix)
+	if len(names) == 0 {
+		return cty.DynamicPseudoType
+	}
+
+	if _, exists := m[childPrefix+"#"]; exists {
+		return cty.List(inferFlatmapElementType(m, childPrefix, names, "#"))
+	}
+	if _, exists := m[childPrefix+"%"]; exists {
+		return cty.Map(inferFlatmapElementType(m, childPrefix, names, "%"))
+	}
+
+	allNumeric := true
+	for _, name := range names {
+		if _, err := strconv.Atoi(name); err != nil {
+			allNumeric = false
+			break
+		}
+	}
+	if allNumeric {
+		return cty.List(inferFlatmapElementType(m, childPrefix, names, ""))
+	}
+
+	return inferFlatmapObjectType(m, childPrefix)
+}
+
+func inferFlatmapObjectType(m map[string]string, prefix string) cty.Type {
+	atys := make(map[string]cty.Type)
+	for _, name := range flatmapChildNames(m, prefix) {
+		if name == "" {
+			// Malformed keys like "" or ".foo" have no usable attribute
+			// name, and at the root would otherwise recurse forever.
+			continue
+		}
+		atys[name] = InferTypeFromFlatmap(m, prefix+name)
+	}
+	return cty.Object(atys)
+}
+
+// inferFlatmapElementType infers a single element type for the collection
+// elements with the given names under the given prefix, ignoring the given
+// count marker.
+func inferFlatmapElementType(m map[string]string, prefix string, names []string, marker string) cty.Type {
+	ety := cty.NilType
+	for _, name := range names {
+		if name == marker || name == "" {
+			continue
+		}
+
+		thisTy := InferTypeFromFlatmap(m, prefix+name)
+		if ety == cty.NilType {
+			ety = thisTy
+			continue
+		}
+		if !ety.Equals(thisTy) {
+			return cty.DynamicPseudoType
+		}
+	}
+
+	if ety == cty.NilType {
+		return cty.DynamicPseudoType
+	}
+	return ety
+}
+
+// flatmapChildNames returns the distinct, sorted first path segments of all
+// keys in the given map that start with the given prefix.
+func flatmapChildNames(m map[string]string, prefix string) []string {
+	seen := make(map[string]struct{})
+	for k := range m {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		name := k[len(prefix):]
+		if idx := strings.Index(name, "."); idx != -1 {
+			name = name[:idx]
+		}
+		seen[name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
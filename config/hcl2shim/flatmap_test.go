@@ -0,0 +1,344 @@
+package hcl2shim
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/config/configschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestValidateFlatmapAgainstSchema(t *testing.T) {
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id": {
+				Type:     cty.String,
+				Computed: true,
+			},
+			"name": {
+				Type:     cty.String,
+				Required: true,
+			},
+			"tags": {
+				Type:     cty.Map(cty.String),
+				Optional: true,
+			},
+		},
+		BlockTypes: map[string]*configschema.NestedBlock{
+			"disk": {
+				Nesting: configschema.NestingList,
+				Block: configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"size": {
+							Type:     cty.Number,
+							Required: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		Input map[string]string
+		Want  []string
+	}{
+		"valid": {
+			map[string]string{
+				"id":          "i-abc123",
+				"name":        "foo",
+				"tags.%":      "1",
+				"tags.Name":   "foo",
+				"disk.#":      "1",
+				"disk.0.size": "10",
+			},
+			nil,
+		},
+		"missing required attribute": {
+			map[string]string{
+				"id": "i-abc123",
+			},
+			[]string{
+				"name: required attribute is missing",
+			},
+		},
+		"stray key": {
+			map[string]string{
+				"name":  "foo",
+				"bogus": "baz",
+			},
+			[]string{
+				"bogus: unexpected key for this schema",
+			},
+		},
+		"missing count marker": {
+			map[string]string{
+				"name":        "foo",
+				"disk.0.size": "10",
+			},
+			[]string{
+				`disk: count marker "disk.#" is missing`,
+			},
+		},
+		"scalar block element": {
+			map[string]string{
+				"name":        "foo",
+				"disk.#":      "2",
+				"disk.0":      "x",
+				"disk.1.size": "10",
+			},
+			[]string{
+				"disk.0: unexpected key for this schema",
+			},
+		},
+		"scalar block": {
+			map[string]string{
+				"name": "foo",
+				"disk": "x",
+			},
+			[]string{
+				"disk: unexpected key for this schema",
+			},
+		},
+		"nested block problems": {
+			map[string]string{
+				"name":         "foo",
+				"disk.#":       "1",
+				"disk.0.bogus": "10",
+			},
+			[]string{
+				"disk.0.bogus: unexpected key for this schema",
+				"disk.0.size: required attribute is missing",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			diags := ValidateFlatmapAgainstSchema(test.Input, schema)
+
+			var got []string
+			for _, diag := range diags {
+				got = append(got, diag.Description().Summary)
+			}
+
+			if len(got) != len(test.Want) {
+				t.Fatalf("wrong number of diagnostics\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+			for i := range got {
+				if got[i] != test.Want[i] {
+					t.Errorf("wrong diagnostic %d\ngot:  %s\nwant: %s", i, got[i], test.Want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateFlatmapAgainstSchema_nilSchema(t *testing.T) {
+	diags := ValidateFlatmapAgainstSchema(map[string]string{
+		"foo": "bar",
+	}, nil)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Err())
+	}
+}
+
+func BenchmarkValidateFlatmapAgainstSchema(b *testing.B) {
+	attrs := map[string]*configschema.Attribute{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		attrs[name] = &configschema.Attribute{
+			Type:     cty.String,
+			Required: true,
+		}
+	}
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id": {
+				Type:     cty.String,
+				Computed: true,
+			},
+		},
+		BlockTypes: map[string]*configschema.NestedBlock{
+			"rule": {
+				Nesting: configschema.NestingSet,
+				Block: configschema.Block{
+					Attributes: attrs,
+				},
+			},
+		},
+	}
+
+	for _, count := range []int{1000, 4000} {
+		m := map[string]string{
+			"id":     "foo",
+			"rule.#": fmt.Sprintf("%d", count),
+		}
+		for i := 0; i < count; i++ {
+			for name := range attrs {
+				m[fmt.Sprintf("rule.%d.%s", 1000000+i, name)] = "x"
+			}
+		}
+
+		b.Run(fmt.Sprintf("%d elements", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if diags := ValidateFlatmapAgainstSchema(m, schema); len(diags) != 0 {
+					b.Fatalf("unexpected diagnostics: %s", diags.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestInferTypeFromFlatmap(t *testing.T) {
+	tests := []struct {
+		Input  map[string]string
+		Prefix string
+		Want   cty.Type
+	}{
+		{
+			map[string]string{},
+			"",
+			cty.EmptyObject,
+		},
+		{
+			map[string]string{
+				"foo": "bar",
+			},
+			"foo",
+			cty.String,
+		},
+		{
+			map[string]string{
+				"foo": "bar",
+			},
+			"baz",
+			cty.DynamicPseudoType,
+		},
+		{
+			map[string]string{
+				"id":        "i-abc123",
+				"name":      "foo",
+				"tags.%":    "2",
+				"tags.Name": "foo",
+				"tags.Env":  "prod",
+			},
+			"",
+			cty.Object(map[string]cty.Type{
+				"id":   cty.String,
+				"name": cty.String,
+				"tags": cty.Map(cty.String),
+			}),
+		},
+		{
+			map[string]string{
+				"foo.#": "2",
+				"foo.0": "a",
+				"foo.1": "b",
+			},
+			"foo",
+			cty.List(cty.String),
+		},
+		{
+			map[string]string{
+				"foo.#": "0",
+			},
+			"foo",
+			cty.List(cty.DynamicPseudoType),
+		},
+		{
+			map[string]string{
+				"foo.0": "a",
+				"foo.1": "b",
+			},
+			"foo",
+			cty.List(cty.String),
+		},
+		{
+			map[string]string{
+				"foo.#":          "2",
+				"foo.12345.name": "a",
+				"foo.12345.size": "1",
+				"foo.67890.name": "b",
+				"foo.67890.size": "2",
+			},
+			"foo",
+			cty.List(cty.Object(map[string]cty.Type{
+				"name": cty.String,
+				"size": cty.String,
+			})),
+		},
+		{
+			map[string]string{
+				"foo.#":        "2",
+				"foo.0":        "a",
+				"foo.1.bar":    "b",
+				"foo.1.baz.#":  "1",
+				"foo.1.baz.0":  "c",
+				"other.nested": "d",
+			},
+			"foo",
+			cty.List(cty.DynamicPseudoType),
+		},
+		{
+			map[string]string{
+				"foo.bar":   "a",
+				"foo.baz.%": "1",
+				"foo.baz.a": "b",
+			},
+			"foo",
+			cty.Object(map[string]cty.Type{
+				"bar": cty.String,
+				"baz": cty.Map(cty.String),
+			}),
+		},
+		{
+			map[string]string{
+				"":    "x",
+				"foo": "bar",
+			},
+			"",
+			cty.Object(map[string]cty.Type{
+				"foo": cty.String,
+			}),
+		},
+		{
+			map[string]string{
+				".foo": "x",
+				"bar":  "baz",
+			},
+			"",
+			cty.Object(map[string]cty.Type{
+				"bar": cty.String,
+			}),
+		},
+		{
+			map[string]string{
+				"foo.#":    "1",
+				"foo.0":    "a",
+				"foo..bar": "b",
+				"foo.":     "c",
+				"baz..qux": "d",
+				"baz.quux": "e",
+			},
+			"",
+			cty.Object(map[string]cty.Type{
+				"foo": cty.List(cty.String),
+				"baz": cty.Object(map[string]cty.Type{
+					"quux": cty.String,
+				}),
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%#v/%q", test.Input, test.Prefix), func(t *testing.T) {
+			got := InferTypeFromFlatmap(test.Input, test.Prefix)
+			if !got.Equals(test.Want) {
+				t.Errorf(
+					"wrong result\ninput:  %#v\nprefix: %q\ngot:    %#v\nwant:   %#v",
+					test.Input, test.Prefix, got, test.Want,
+				)
+			}
+		})
+	}
+}
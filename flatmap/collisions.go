@@ -0,0 +1,39 @@
+package flatmap
+
+import (
+	"sort"
+	"strings"
+)
+
+// DetectCaseCollisions returns groups of keys from the given map that are
+// distinct but become equal when compared case-insensitively, such as
+// "Foo" and "foo".
+//
+// A case-sensitive decode treats such keys as separate entries, which is
+// rarely what was intended, so this is intended to be used by tooling that
+// wishes to warn about them. Each group is sorted, and the groups themselves
+// are sorted by their first key so that the result is deterministic. If
+// there are no collisions then the result is nil.
+func DetectCaseCollisions(m map[string]string) [][]string {
+	folded := make(map[string][]string)
+	for k := range m {
+		lk := strings.ToLower(k)
+		folded[lk] = append(folded[lk], k)
+	}
+
+	var result [][]string
+	for _, keys := range folded {
+		if len(keys) < 2 {
+			continue
+		}
+
+		sort.Strings(keys)
+		result = append(result, keys)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+
+	return result
+}
@@ -0,0 +1,53 @@
+package flatmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectCaseCollisions(t *testing.T) {
+	cases := []struct {
+		Input  map[string]string
+		Output [][]string
+	}{
+		{
+			Input: map[string]string{
+				"foo": "bar",
+				"bar": "baz",
+			},
+			Output: nil,
+		},
+
+		{
+			Input: map[string]string{
+				"Foo": "bar",
+				"foo": "baz",
+				"bar": "baz",
+			},
+			Output: [][]string{
+				{"Foo", "foo"},
+			},
+		},
+
+		{
+			Input: map[string]string{
+				"tags.Name": "a",
+				"tags.NAME": "b",
+				"tags.name": "c",
+				"Foo":       "bar",
+				"foo":       "baz",
+			},
+			Output: [][]string{
+				{"Foo", "foo"},
+				{"tags.NAME", "tags.Name", "tags.name"},
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		actual := DetectCaseCollisions(tc.Input)
+		if !reflect.DeepEqual(actual, tc.Output) {
+			t.Fatalf("case %d bad: %#v", i, actual)
+		}
+	}
+}
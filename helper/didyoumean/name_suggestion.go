@@ -1,9 +1,14 @@
 package didyoumean
 
 import (
-	"github.com/agext/levenshtein"
+	"unicode/utf8"
 )
 
+// suggestionThreshold is the maximum edit distance, exclusive, at which a
+// candidate is considered close enough to be suggested. It was determined
+// experimentally.
+const suggestionThreshold = 3
+
 // NameSuggestion tries to find a name from the given slice of suggested names
 // that is close to the given name and returns it if found. If no suggestion
 // is close enough, returns the empty string.
@@ -11,14 +16,74 @@ import (
 // The suggestions are tried in order, so earlier suggestions take precedence
 // if the given string is similar to two or more suggestions.
 //
-// This function is intended to be used with a relatively-small number of
-// suggestions. It's not optimized for hundreds or thousands of them.
+// Closeness is measured as an edit distance in which swapping two adjacent
+// characters counts as a single edit, since transposition typos such as
+// "lcoal" for "local" are common in identifiers.
+//
+// The edit distance between two strings can be no smaller than the
+// difference in their lengths in characters, so candidates whose length
+// differs too much from the given name are skipped without computing the
+// full distance. This keeps the cost low even for large sets of suggestions,
+// where most candidates can be rejected cheaply.
 func NameSuggestion(given string, suggestions []string) string {
+	givenLen := utf8.RuneCountInString(given)
 	for _, suggestion := range suggestions {
-		dist := levenshtein.Distance(given, suggestion, nil)
-		if dist < 3 { // threshold determined experimentally
+		lenDiff := givenLen - utf8.RuneCountInString(suggestion)
+		if lenDiff < 0 {
+			lenDiff = -lenDiff
+		}
+		if lenDiff >= suggestionThreshold {
+			continue
+		}
+
+		dist := distance(given, suggestion)
+		if dist < suggestionThreshold {
 			return suggestion
 		}
 	}
 	return ""
 }
+
+// distance returns the optimal string alignment distance between the two
+// given strings: the number of single-character insertions, deletions,
+// substitutions and adjacent transpositions needed to turn one into the
+// other, with no substring edited more than once.
+func distance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	// We keep only the last three rows of the matrix, since a transposition
+	// looks back two rows.
+	prev2 := make([]int, len(br)+1)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			d := prev[j] + 1 // deletion
+			if ins := cur[j-1] + 1; ins < d {
+				d = ins
+			}
+			if sub := prev[j-1] + cost; sub < d {
+				d = sub
+			}
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				if trans := prev2[j-2] + 1; trans < d {
+					d = trans
+				}
+			}
+			cur[j] = d
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(br)]
+}
@@ -1,6 +1,8 @@
 package didyoumean
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -37,6 +39,10 @@ func TestNameSuggestion(t *testing.T) {
 		{"nul", "null"},
 		{"unll", "null"},
 		{"nll", "null"},
+
+		// Two transpositions are only two edits, whereas plain Levenshtein
+		// distance would count each as two substitutions.
+		{"rteu", "true"},
 	}
 
 	for _, test := range tests {
@@ -51,3 +57,118 @@ func TestNameSuggestion(t *testing.T) {
 		})
 	}
 }
+
+func TestNameSuggestionLengthFilter(t *testing.T) {
+	// The length filter must agree with the full distance calculation,
+	// including for multi-byte characters where byte and character lengths
+	// differ.
+	tests := []struct {
+		Input       string
+		Suggestions []string
+		Want        string
+	}{
+		{"foo", []string{"foobarbaz", "fooo"}, "fooo"},
+		{"foo", []string{"foobarbaz", "foobar"}, ""},
+		{"fo", []string{"f", "foobar"}, "f"},
+		{"héllo", []string{"hello"}, "hello"},
+		{"ééé", []string{"e"}, ""},
+		{"éé", []string{"e"}, "e"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			got := NameSuggestion(test.Input, test.Suggestions)
+			if got != test.Want {
+				t.Errorf(
+					"wrong result\ninput: %q\ngot:   %q\nwant:  %q",
+					test.Input, got, test.Want,
+				)
+			}
+		})
+	}
+}
+
+func TestNameSuggestionTransposition(t *testing.T) {
+	var names = []string{"local", "variable", "resource", "module"}
+
+	tests := []struct {
+		Input, Want string
+	}{
+		{"lcoal", "local"},
+		{"vairalbe", "variable"},
+		{"rseoucre", "resource"},
+		{"mdouel", "module"},
+		{"elbairav", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			got := NameSuggestion(test.Input, names)
+			if got != test.Want {
+				t.Errorf(
+					"wrong result\ninput: %q\ngot:   %q\nwant:  %q",
+					test.Input, got, test.Want,
+				)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		A, B string
+		Want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abc", "abc", 0},
+		{"abc", "abd", 1},
+		{"abc", "ab", 1},
+		{"ab", "ba", 1},
+		{"lcoal", "local", 1},
+		{"vairalbe", "variable", 2},
+		{"ca", "abc", 3},
+		{"héllo", "hlélo", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.A+"/"+test.B, func(t *testing.T) {
+			got := distance(test.A, test.B)
+			if got != test.Want {
+				t.Errorf(
+					"wrong result\na:    %q\nb:    %q\ngot:  %d\nwant: %d",
+					test.A, test.B, got, test.Want,
+				)
+			}
+		})
+	}
+}
+
+func BenchmarkNameSuggestion(b *testing.B) {
+	// 1000 candidates of varying lengths, none of which is close to the
+	// given name, so every candidate must be considered.
+	const given = "instance_count"
+	suggestions := make([]string, 1000)
+	for i := range suggestions {
+		suggestions[i] = fmt.Sprintf("%s_%d", strings.Repeat("candidate", i%5+1), i)
+	}
+
+	b.Run("filtered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NameSuggestion(given, suggestions)
+		}
+	})
+
+	// The unfiltered baseline computes the full distance for every
+	// candidate, as NameSuggestion would without its length filter.
+	b.Run("unfiltered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, suggestion := range suggestions {
+				if distance(given, suggestion) < suggestionThreshold {
+					break
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/config/hcl2shim"
+	"github.com/hashicorp/terraform/tfdiags"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// DecodeModuleOutputs decodes the outputs recorded in the given module state
+// into a single object value with one attribute for each entry in
+// outputTypes, converting each stored value to its given type.
+//
+// Outputs that are not present in the state, such as those of a module that
+// has not yet been applied, are returned as null values of their given
+// types. Outputs present in the state but not named in outputTypes are
+// ignored. If a stored value cannot be converted to its given type then an
+// error diagnostic is returned and the corresponding attribute is an unknown
+// value of the given type.
+func DecodeModuleOutputs(ms *ModuleState, outputTypes map[string]cty.Type) (cty.Value, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	names := make([]string, 0, len(outputTypes))
+	for name := range outputTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if ms != nil {
+		ms.Lock()
+		defer ms.Unlock()
+	}
+
+	vals := make(map[string]cty.Value, len(outputTypes))
+	for _, name := range names {
+		ty := outputTypes[name]
+
+		var os *OutputState
+		if ms != nil {
+			os = ms.Outputs[name]
+		}
+		if os == nil {
+			vals[name] = cty.NullVal(ty)
+			continue
+		}
+
+		os.Lock()
+		raw := hcl2shim.HCL2ValueFromConfigValue(os.Value)
+		os.Unlock()
+
+		val, err := convert.Convert(raw, ty)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf(
+				"Invalid value for output %q in state: %s", name, err,
+			))
+			val = cty.UnknownVal(ty)
+		}
+		vals[name] = val
+	}
+
+	return cty.ObjectVal(vals), diags
+}
@@ -0,0 +1,74 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecodeModuleOutputs(t *testing.T) {
+	ms := &ModuleState{
+		Path: rootModulePath,
+		Outputs: map[string]*OutputState{
+			"address": {
+				Type:  "string",
+				Value: "10.0.0.1",
+			},
+			"zones": {
+				Type:  "list",
+				Value: []interface{}{"us-west-2a", "us-west-2b"},
+			},
+			"undeclared": {
+				Type:  "string",
+				Value: "ignored",
+			},
+		},
+	}
+
+	got, diags := DecodeModuleOutputs(ms, map[string]cty.Type{
+		"address": cty.String,
+		"zones":   cty.List(cty.String),
+		"pending": cty.Map(cty.String),
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	want := cty.ObjectVal(map[string]cty.Value{
+		"address": cty.StringVal("10.0.0.1"),
+		"zones": cty.ListVal([]cty.Value{
+			cty.StringVal("us-west-2a"),
+			cty.StringVal("us-west-2b"),
+		}),
+		"pending": cty.NullVal(cty.Map(cty.String)),
+	})
+	if !got.RawEquals(want) {
+		t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDecodeModuleOutputs_invalid(t *testing.T) {
+	ms := &ModuleState{
+		Path: rootModulePath,
+		Outputs: map[string]*OutputState{
+			"zones": {
+				Type:  "string",
+				Value: "us-west-2a",
+			},
+		},
+	}
+
+	got, diags := DecodeModuleOutputs(ms, map[string]cty.Type{
+		"zones": cty.List(cty.String),
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+
+	want := cty.ObjectVal(map[string]cty.Value{
+		"zones": cty.UnknownVal(cty.List(cty.String)),
+	})
+	if !got.RawEquals(want) {
+		t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}